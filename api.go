@@ -0,0 +1,231 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var listenAddr = flag.String("listen", ":8080", "address for the read-only query API to listen on")
+
+const defaultPerPage = 50
+
+var (
+	emuHistoryStmt   *sql.Stmt
+	trainHistoryStmt *sql.Stmt
+	bureauLatestStmt *sql.Stmt
+	activityStmt     *sql.Stmt
+)
+
+// startAPIServer prepares the query statements against the shared db handle
+// and serves the read-only REST API until the process exits.
+func startAPIServer() {
+	var err error
+	emuHistoryStmt, err = db.Prepare(`
+		SELECT date, train_no FROM emu_log WHERE emu_no = ? ORDER BY date DESC
+	`)
+	checkFatal(err)
+
+	trainHistoryStmt, err = db.Prepare(`
+		SELECT date, emu_no FROM emu_log WHERE train_no = ? ORDER BY date DESC
+	`)
+	checkFatal(err)
+
+	bureauLatestStmt, err = db.Prepare(`
+		SELECT emu_log.emu_no, MAX(emu_log.date), emu_log.train_no
+		FROM emu_log
+		JOIN emu_qrcode ON emu_qrcode.emu_no = emu_log.emu_no
+		WHERE emu_qrcode.emu_bureau = ?
+		GROUP BY emu_log.emu_no
+		ORDER BY emu_log.emu_no ASC
+	`)
+	checkFatal(err)
+
+	activityStmt, err = db.Prepare(`
+		SELECT emu_log.date, emu_log.emu_no, emu_log.train_no, emu_qrcode.emu_bureau
+		FROM emu_log
+		JOIN emu_qrcode ON emu_qrcode.emu_no = emu_log.emu_no
+		WHERE emu_log.date >= ? AND emu_log.date <= ?
+		  AND (? = '' OR emu_qrcode.emu_bureau = ?)
+		ORDER BY emu_log.date DESC
+		LIMIT ? OFFSET ?
+	`)
+	checkFatal(err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/emu/", handleEmuHistory)
+	mux.HandleFunc("/train/", handleTrainHistory)
+	mux.HandleFunc("/bureau/", handleBureauLatest)
+	mux.HandleFunc("/activity", handleActivity)
+
+	log.Info().Msgf("query API listening on %s", *listenAddr)
+	checkFatal(http.ListenAndServe(*listenAddr, mux))
+}
+
+func handleEmuHistory(w http.ResponseWriter, r *http.Request) {
+	emuNo := strings.TrimPrefix(r.URL.Path, "/emu/")
+	if emuNo == "" {
+		http.Error(w, "missing emu number", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := emuHistoryStmt.Query(emuNo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type entry struct {
+		Date    string `json:"date"`
+		TrainNo string `json:"train_no"`
+	}
+	var history []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.Date, &e.TrainNo); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		history = append(history, e)
+	}
+	writeJSON(w, JsonObject{"emu_no": emuNo, "history": history})
+}
+
+func handleTrainHistory(w http.ResponseWriter, r *http.Request) {
+	trainNo := strings.TrimPrefix(r.URL.Path, "/train/")
+	if trainNo == "" {
+		http.Error(w, "missing train number", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := trainHistoryStmt.Query(trainNo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type entry struct {
+		Date  string `json:"date"`
+		EmuNo string `json:"emu_no"`
+	}
+	var history []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.Date, &e.EmuNo); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		history = append(history, e)
+	}
+	writeJSON(w, JsonObject{"train_no": trainNo, "history": history})
+}
+
+func handleBureauLatest(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/bureau/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "latest" {
+		http.NotFound(w, r)
+		return
+	}
+	code := parts[0]
+
+	rows, err := bureauLatestStmt.Query(code)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type entry struct {
+		EmuNo   string `json:"emu_no"`
+		Date    string `json:"date"`
+		TrainNo string `json:"train_no"`
+	}
+	var latest []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.EmuNo, &e.Date, &e.TrainNo); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		latest = append(latest, e)
+	}
+	writeJSON(w, JsonObject{"bureau": code, "latest": latest})
+}
+
+func handleActivity(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	start, err := parseDateParam(q.Get("start"), "0000-01-01")
+	if err != nil {
+		http.Error(w, "invalid start: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	end, err := parseDateParam(q.Get("end"), "9999-12-31")
+	if err != nil {
+		http.Error(w, "invalid end: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	bureau := q.Get("bureau")
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(q.Get("perpage"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+
+	rows, err := activityStmt.Query(start, end, bureau, bureau, perPage, (page-1)*perPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type entry struct {
+		Date    string `json:"date"`
+		EmuNo   string `json:"emu_no"`
+		TrainNo string `json:"train_no"`
+		Bureau  string `json:"bureau"`
+	}
+	var activity []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.Date, &e.EmuNo, &e.TrainNo, &e.Bureau); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		activity = append(activity, e)
+	}
+	writeJSON(w, JsonObject{"page": page, "perpage": perPage, "activity": activity})
+}
+
+// parseDateParam parses an RFC3339 timestamp and reduces it to the
+// YYYY-MM-DD form emu_log.date is stored in, falling back to fallback
+// when s is empty.
+func parseDateParam(s, fallback string) (string, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("failed to encode API response")
+	}
+}
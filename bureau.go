@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// Bureau describes one railway bureau's scraping job: how to resolve a
+// train number from a vehicle's QR code, and the schedule/credentials it
+// should run under. Bureaus register themselves via RegisterBureau from
+// their own init(), rather than being listed in one shared slice.
+type Bureau struct {
+	Code    string
+	Name    string
+	TrainNo func(this *Bureau, ctx context.Context, qrCode string) (trainNo, date string, err error)
+	Info    func(ctx context.Context, qrCode string) (info JsonObject, err error)
+	Scan    func()
+
+	// RequestDelay overrides the global requestDelay between vehicles for
+	// this bureau.
+	RequestDelay time.Duration
+	// DailyWindow restricts scraping to a time-of-day range, measured as
+	// an offset since local midnight. A zero value disables the window.
+	DailyWindow struct {
+		Start, End time.Duration
+	}
+	// Enabled gates the bureau out of iterBureaus entirely when false.
+	Enabled bool
+	// APIKey holds a credential for bureaus whose upstream API requires one.
+	// It is set from the config file; the H and P bureaus do not read it.
+	APIKey string
+}
+
+var bureaus []Bureau
+
+// RegisterBureau adds b to the set of bureaus iterBureaus sweeps. It is
+// meant to be called from each bureau's init().
+func RegisterBureau(b Bureau) {
+	bureaus = append(bureaus, b)
+}
+
+// withinWindow reports whether t falls inside b.DailyWindow, treating a
+// zero-value window as "always".
+func (b *Bureau) withinWindow(t time.Time) bool {
+	if b.DailyWindow.Start == 0 && b.DailyWindow.End == 0 {
+		return true
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	sinceMidnight := t.Sub(midnight)
+	return sinceMidnight >= b.DailyWindow.Start && sinceMidnight <= b.DailyWindow.End
+}
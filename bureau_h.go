@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func init() {
+	RegisterBureau(Bureau{
+		Code:         "H",
+		Name:         "中国铁路上海局集团有限公司",
+		RequestDelay: requestDelay,
+		DailyWindow: struct{ Start, End time.Duration }{
+			Start: startTime,
+			End:   endTime,
+		},
+		Enabled: true,
+		TrainNo: func(this *Bureau, ctx context.Context, pqCode string) (trainNo, date string, err error) {
+			var info JsonObject
+			info, err = this.Info(ctx, pqCode)
+			if err == nil {
+				trainNo = info["trainName"].(string)
+				date = time.Now().Format("2006-01-02")
+			}
+			return
+		},
+		Info: func(ctx context.Context, pqCode string) (info JsonObject, err error) {
+			const api = "https://g.xiuxiu365.cn/railway_api/web/index/train"
+			query := url.Values{"pqCode": {pqCode}}.Encode()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, api+"?"+query, nil)
+			if err != nil {
+				return
+			}
+			resp, err := doRequest(ctx, "H", req)
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+
+			var result struct {
+				Code int
+				Msg  string
+				Data JsonObject
+			}
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			info = result.Data
+			return
+		},
+	})
+}
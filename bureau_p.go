@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterBureau(Bureau{
+		Code:         "P",
+		Name:         "中国铁路北京局集团有限公司",
+		RequestDelay: requestDelay,
+		DailyWindow: struct{ Start, End time.Duration }{
+			Start: startTime,
+			End:   endTime,
+		},
+		Enabled: true,
+		TrainNo: func(this *Bureau, ctx context.Context, qrCode string) (trainNo, date string, err error) {
+			var info JsonObject
+			info, err = this.Info(ctx, qrCode)
+			if err == nil {
+				trainNo = info["TrainnoId"].(string)
+				date = info["TrainnoDate"].(string)
+			}
+			return
+		},
+		Info: func(ctx context.Context, qrCode string) (info JsonObject, err error) {
+			const api = "https://aymaoto.jtlf.cn/webapi/otoshopping/ewh_getqrcodetrainnoinfo"
+			const key = "qrcode=%s&key=ltRsjkiM8IRbC80Ni1jzU5jiO6pJvbKd"
+			sign := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf(key, qrCode))))
+			form := url.Values{"qrCode": {qrCode}, "sign": {sign}}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, api, strings.NewReader(form.Encode()))
+			if err != nil {
+				return
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			resp, err := doRequest(ctx, "P", req)
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+
+			var result struct {
+				State int
+				Msg   string
+				Data  struct {
+					TrainInfo JsonObject
+					UrlStr    string
+				}
+			}
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			info = result.Data.TrainInfo
+			return
+		},
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+var configPath = flag.String("config", "emu-log.toml", "path to the bureau configuration file")
+
+// bureauConfig overrides a registered Bureau's scheduling/credential
+// fields. Durations are parsed with time.ParseDuration (e.g. "90s").
+type bureauConfig struct {
+	RequestDelay string `toml:"request_delay"`
+	DailyWindow  struct {
+		Start string `toml:"start"`
+		End   string `toml:"end"`
+	} `toml:"daily_window"`
+	Enabled *bool  `toml:"enabled"`
+	APIKey  string `toml:"api_key"`
+}
+
+type fileConfig struct {
+	Bureau map[string]bureauConfig `toml:"bureau"`
+}
+
+// loadConfig reads path, returning a zero-value fileConfig when it doesn't
+// exist so an absent -config file leaves the built-in bureau defaults alone.
+func loadConfig(path string) (fileConfig, error) {
+	var cfg fileConfig
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+	_, err := toml.DecodeFile(path, &cfg)
+	return cfg, err
+}
+
+// applyConfig overlays *configPath onto the registered bureaus in place.
+// Bureaus absent from the config file keep their registration defaults.
+func applyConfig() {
+	cfg, err := loadConfig(*configPath)
+	checkFatal(err)
+
+	for i := range bureaus {
+		b := &bureaus[i]
+		bc, ok := cfg.Bureau[b.Code]
+		if !ok {
+			continue
+		}
+		if bc.RequestDelay != "" {
+			d, err := time.ParseDuration(bc.RequestDelay)
+			checkFatal(err)
+			b.RequestDelay = d
+		}
+		if bc.DailyWindow.Start != "" {
+			d, err := time.ParseDuration(bc.DailyWindow.Start)
+			checkFatal(err)
+			b.DailyWindow.Start = d
+		}
+		if bc.DailyWindow.End != "" {
+			d, err := time.ParseDuration(bc.DailyWindow.End)
+			checkFatal(err)
+			b.DailyWindow.End = d
+		}
+		if bc.Enabled != nil {
+			b.Enabled = *bc.Enabled
+		}
+		if bc.APIKey != "" {
+			b.APIKey = bc.APIKey
+		}
+	}
+}
@@ -1,14 +1,14 @@
 package main
 
 import (
-	"crypto/md5"
+	"context"
 	"database/sql"
-	"encoding/json"
-	"fmt"
+	"flag"
 	"net/http"
-	"net/url"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -21,89 +21,12 @@ type (
 	LogRecord  struct {
 		date, emuNo, trainNo string
 	}
-	Bureau struct {
-		Code    string
-		Name    string
-		TrainNo func(this *Bureau, qrCode string) (trainNo, date string, err error)
-		Info    func(qrCode string) (info JsonObject, err error)
-		Scan    func()
-	}
 )
 
-var bureaus = []Bureau{
-	Bureau{
-		Code: "H",
-		Name: "中国铁路上海局集团有限公司",
-		TrainNo: func(this *Bureau, pqCode string) (trainNo, date string, err error) {
-			var info JsonObject
-			info, err = this.Info(pqCode)
-			if err == nil {
-				trainNo = info["trainName"].(string)
-				date = time.Now().Format("2006-01-02")
-			}
-			return
-		},
-		Info: func(pqCode string) (info JsonObject, err error) {
-			const api = "https://g.xiuxiu365.cn/railway_api/web/index/train"
-			query := url.Values{"pqCode": {pqCode}}.Encode()
-			resp, err := httpClient.Get(api + "?" + query)
-			if err != nil {
-				return
-			}
-			defer resp.Body.Close()
-
-			var result struct {
-				Code int
-				Msg  string
-				Data JsonObject
-			}
-			err = json.NewDecoder(resp.Body).Decode(&result)
-			info = result.Data
-			return
-		},
-	},
-	Bureau{
-		Code: "P",
-		Name: "中国铁路北京局集团有限公司",
-		TrainNo: func(this *Bureau, qrCode string) (trainNo, date string, err error) {
-			var info JsonObject
-			info, err = this.Info(qrCode)
-			if err == nil {
-				trainNo = info["TrainnoId"].(string)
-				date = info["TrainnoDate"].(string)
-			}
-			return
-		},
-		Info: func(qrCode string) (info JsonObject, err error) {
-			const api = "https://aymaoto.jtlf.cn/webapi/otoshopping/ewh_getqrcodetrainnoinfo"
-			const key = "qrcode=%s&key=ltRsjkiM8IRbC80Ni1jzU5jiO6pJvbKd"
-			sign := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf(key, qrCode))))
-			form := url.Values{"qrCode": {qrCode}, "sign": {sign}}
-			resp, err := httpClient.PostForm(api, form)
-			if err != nil {
-				return
-			}
-			var result struct {
-				State int
-				Msg   string
-				Data  struct {
-					TrainInfo JsonObject
-					UrlStr    string
-				}
-			}
-			err = json.NewDecoder(resp.Body).Decode(&result)
-			info = result.Data.TrainInfo
-			return
-		},
-	},
-}
-
 var (
-	httpClient = &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	wg sync.WaitGroup
-	db *sql.DB
+	httpClient = &http.Client{}
+	wg         sync.WaitGroup
+	db         *sql.DB
 )
 
 const (
@@ -115,10 +38,28 @@ const (
 )
 
 func main() {
+	flag.Parse()
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	checkDatabase()
+	applyConfig()
+
+	if *exportPath != "" {
+		runExport(*exportPath)
+		checkFatal(db.Close())
+		return
+	}
+	if *importPath != "" {
+		runImport(*importPath)
+		checkFatal(db.Close())
+		return
+	}
+
 	checkLocalTimezone()
 	checkInternetConnection()
-	checkDatabase()
+	go startAPIServer()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	var nextRun time.Time
 	for {
@@ -134,21 +75,33 @@ func main() {
 		} else {
 			nextRun = now.Truncate(repeatInterval).Add(repeatInterval)
 		}
-		iterBureaus()
+		iterBureaus(ctx)
 		log.Info().Msgf("next schduled run: %v", nextRun)
-		time.Sleep(time.Until(nextRun))
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("shutdown signal received, waiting for in-flight jobs")
+			wg.Wait()
+			checkFatal(db.Close())
+			return
+		case <-time.After(time.Until(nextRun)):
+		}
 	}
 }
 
-func iterBureaus() {
+func iterBureaus(ctx context.Context) {
+	now := time.Now()
 	for i := range bureaus {
+		if !bureaus[i].Enabled || !bureaus[i].withinWindow(now) {
+			continue
+		}
 		wg.Add(1)
-		go bureaus[i].iterVehicles()
+		go bureaus[i].iterVehicles(ctx)
 	}
 	wg.Wait()
 }
 
-func (b *Bureau) iterVehicles() {
+func (b *Bureau) iterVehicles(ctx context.Context) {
 	log.Info().Msgf("job started: %s", b.Name)
 	defer wg.Done()
 
@@ -165,8 +118,15 @@ func (b *Bureau) iterVehicles() {
 	for rows.Next() {
 		var emuNo, qrCode, id string
 		checkFatal(rows.Scan(&emuNo, &qrCode, &id))
-		time.Sleep(requestDelay)
-		trainNo, date, _ := b.TrainNo(b, qrCode)
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("job cancelled: %s", b.Name)
+			return
+		case <-time.After(b.RequestDelay):
+		}
+
+		trainNo, date, _ := b.TrainNo(b, ctx, qrCode)
 		log.Debug().Msgf("%s: %s/%s", emuNo, b.Code, trainNo)
 		if trainNo != "" {
 			_, err := db.Exec(
@@ -197,7 +157,7 @@ func checkLocalTimezone() {
 
 func checkInternetConnection() {
 	start := time.Now()
-	_, err := bureaus[0].Info("PQ0123456")
+	_, err := bureaus[0].Info(context.Background(), "PQ0123456")
 	checkFatal(err)
 	log.Info().Msgf(
 		"internet connection ok, round-trip delay %v",
@@ -209,7 +169,6 @@ func checkDatabase() {
 	dbConn, err := sql.Open("sqlite3", "./emu_log.db")
 	checkFatal(err)
 	db = dbConn
-	// TODO: defer db.Close()
 
 	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS emu_log (
 		date        VARCHAR NOT NULL,
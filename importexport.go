@@ -0,0 +1,114 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	exportPath  = flag.String("export", "", "export emu_log records to a JSON file and exit")
+	importPath  = flag.String("import", "", "import emu_log records from a JSON file and exit")
+	exportSince = flag.String("export-since", "", "only export records on or after this date (YYYY-MM-DD)")
+)
+
+const exportVersion = 1
+
+type exportRecord struct {
+	Date    string `json:"date"`
+	EmuNo   string `json:"emu_no"`
+	Bureau  string `json:"bureau"`
+	TrainNo string `json:"train_no"`
+}
+
+type exportFile struct {
+	Version    int            `json:"version"`
+	ExportedAt string         `json:"exported_at"`
+	Records    []exportRecord `json:"records"`
+}
+
+// runExport writes every emu_log row (optionally filtered by -export-since)
+// to path as an exportFile.
+func runExport(path string) {
+	since := *exportSince
+	if since == "" {
+		since = "0000-00-00"
+	}
+
+	rows, err := db.Query(`
+		SELECT emu_log.date, emu_log.emu_no, emu_qrcode.emu_bureau, emu_log.train_no
+		FROM emu_log
+		LEFT JOIN emu_qrcode ON emu_qrcode.emu_no = emu_log.emu_no
+		WHERE emu_log.date >= ?
+		ORDER BY emu_log.date ASC
+	`, since)
+	checkFatal(err)
+	defer rows.Close()
+
+	out := exportFile{
+		Version:    exportVersion,
+		ExportedAt: time.Now().Format(time.RFC3339),
+	}
+	for rows.Next() {
+		var rec exportRecord
+		var bureau sql.NullString
+		checkFatal(rows.Scan(&rec.Date, &rec.EmuNo, &bureau, &rec.TrainNo))
+		rec.Bureau = bureau.String
+		out.Records = append(out.Records, rec)
+	}
+
+	f, err := os.Create(path)
+	checkFatal(err)
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	checkFatal(enc.Encode(out))
+
+	log.Info().Msgf("exported %d records to %s", len(out.Records), path)
+}
+
+// runImport loads an exportFile from path and inserts its records into
+// emu_log inside a single transaction, ignoring rows already present.
+func runImport(path string) {
+	f, err := os.Open(path)
+	checkFatal(err)
+	defer f.Close()
+
+	var in exportFile
+	checkFatal(json.NewDecoder(f).Decode(&in))
+
+	tx, err := db.Begin()
+	checkFatal(err)
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO emu_log VALUES (?, ?, ?)`)
+	checkFatal(err)
+	defer stmt.Close()
+
+	var inserted, skipped, malformed int
+	for _, rec := range in.Records {
+		if rec.Date == "" || rec.EmuNo == "" || rec.TrainNo == "" {
+			malformed++
+			continue
+		}
+		res, err := stmt.Exec(rec.Date, rec.EmuNo, rec.TrainNo)
+		checkFatal(err)
+		n, err := res.RowsAffected()
+		checkFatal(err)
+		if n == 0 {
+			skipped++
+		} else {
+			inserted++
+		}
+	}
+
+	checkFatal(tx.Commit())
+	log.Info().Msgf(
+		"import done: %d inserted, %d skipped, %d malformed",
+		inserted, skipped, malformed,
+	)
+}
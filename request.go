@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxRetry is the total number of attempts: an initial try plus three
+	// retries, with sleeps of 1s, 2s and 4s between them.
+	maxRetry       = 4
+	retryInterval  = time.Second
+	requestTimeout = 5 * time.Second
+)
+
+// userAgents is a small pool rotated across retries so a bureau's API
+// doesn't see the exact same client fingerprint hammer it three times in a row.
+var userAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+	"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+}
+
+// cancelOnCloseBody runs cancel once the wrapped body is closed, so a
+// successful attempt's per-request context is released when the caller is
+// done reading the response instead of leaking until requestTimeout fires.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// doRequest executes req against httpClient, making up to maxRetry attempts
+// with a doubling backoff on network errors and 5xx responses. Each attempt
+// gets its own requestTimeout budget derived from ctx, so backoff sleeps
+// don't eat into a later attempt's deadline. bureau is only used to label
+// the retry log fields.
+func doRequest(ctx context.Context, bureau string, req *http.Request) (resp *http.Response, err error) {
+	interval := retryInterval
+	status := 0
+	for attempt := 1; attempt <= maxRetry; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		attemptReq := req.Clone(attemptCtx)
+		if req.GetBody != nil {
+			// Clone doesn't copy Body, so a retry after a POST would
+			// otherwise replay the drained reader from the first attempt.
+			attemptReq.Body, err = req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+		attemptReq.Header.Set("User-Agent", userAgents[attempt%len(userAgents)])
+
+		resp, err = httpClient.Do(attemptReq)
+		status = 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		log.Debug().
+			Str("bureau", bureau).
+			Int("attempt", attempt).
+			Int("status", status).
+			Msg("bureau request")
+
+		if err == nil && status < http.StatusInternalServerError {
+			resp.Body = cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+		if attempt == maxRetry {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+	log.Warn().
+		Str("bureau", bureau).
+		Int("attempts", maxRetry).
+		Msg("giving up on bureau request")
+	if err == nil {
+		err = fmt.Errorf("giving up after %d attempts, last status %d", maxRetry, status)
+	}
+	return nil, err
+}